@@ -0,0 +1,63 @@
+package main
+
+import (
+	"cwrpc"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+type Foo int
+
+type Args struct{ Num1, Num2 int }
+
+func (f Foo) Sum(args Args, reply *int) error {
+	*reply = args.Num1 + args.Num2
+	return nil
+}
+
+func startServer(addr chan string) {
+	var foo Foo
+	if err := cwrpc.Register(&foo); err != nil {
+		log.Fatal("register error:", err)
+	}
+	// pick a free port
+	//  启动一个 tcp conn
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatal("network error:", err)
+	}
+	log.Println("start rpc server on", l.Addr())
+	addr <- l.Addr().String()
+	cwrpc.Accept(l)
+}
+
+func main() {
+	log.SetFlags(0)
+	addr := make(chan string)
+	// 启动服务
+	go startServer(addr)
+
+	// 客户端建立连接
+	client, _ := cwrpc.Dial("tcp", <-addr)
+	defer func() { _ = client.Close() }()
+
+	time.Sleep(time.Second)
+	// send request & receive response
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			args := &Args{Num1: i, Num2: i * i}
+			var reply int
+			if err := client.Call("Foo.Sum", args, &reply); err != nil {
+				log.Fatal("call Foo.Sum error:", err)
+			}
+			fmt.Printf("%d + %d = %d\n", args.Num1, args.Num2, reply)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"cwrpc"
+	"log"
+	"net"
+	"time"
+)
+
+type Foo int
+
+type Args struct{ Num1, Num2 int }
+
+func (f Foo) Sum(args Args, reply *int) error {
+	*reply = args.Num1 + args.Num2
+	return nil
+}
+
+// Sleep 故意睡眠, 用于演示 HandleTimeout
+func (f Foo) Sleep(args Args, reply *int) error {
+	time.Sleep(time.Second * 2)
+	*reply = args.Num1 + args.Num2
+	return nil
+}
+
+func startServer(addr chan string) {
+	var foo Foo
+	if err := cwrpc.Register(&foo); err != nil {
+		log.Fatal("register error:", err)
+	}
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatal("network error:", err)
+	}
+	log.Println("start rpc server on", l.Addr())
+	addr <- l.Addr().String()
+	cwrpc.Accept(l)
+}
+
+func main() {
+	log.SetFlags(0)
+	addrCh := make(chan string)
+	go startServer(addrCh)
+	serverAddr := <-addrCh
+
+	client, _ := cwrpc.Dial("tcp", serverAddr)
+	defer func() { _ = client.Close() }()
+
+	time.Sleep(time.Second)
+	var reply int
+	if err := client.Call("Foo.Sum", &Args{Num1: 1, Num2: 2}, &reply); err != nil {
+		log.Fatal("call Foo.Sum error:", err)
+	}
+	log.Println("Foo.Sum:", reply)
+
+	// HandleTimeout 设置为 1s, Foo.Sleep 需要 2s, 预期会超时
+	client2, _ := cwrpc.Dial("tcp", serverAddr, &cwrpc.Option{
+		MagicNumber:   cwrpc.MagicNumber,
+		HandleTimeout: time.Second,
+	})
+	defer func() { _ = client2.Close() }()
+	if err := client2.Call("Foo.Sleep", &Args{Num1: 1, Num2: 2}, &reply); err != nil {
+		log.Println("expected timeout error:", err)
+	}
+}
@@ -0,0 +1,55 @@
+package cwrpc
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+)
+
+const debugText = `<html>
+	<body>
+	<title>CodeWave RPC Services</title>
+	{{range .}}
+	<hr>
+	Service {{.Name}}
+	<hr>
+		<table>
+		<th align=center>Method</th><th align=center>Calls</th>
+		{{range $name, $mtype := .Method}}
+			<tr>
+			<td align=left font=fixed>{{$name}}({{$mtype.ArgType}}, {{$mtype.ReplyType}}) error</td>
+			<td align=center>{{$mtype.NumCalls}}</td>
+			</tr>
+		{{end}}
+		</table>
+	{{end}}
+	</body>
+	</html>`
+
+var debug = template.Must(template.New("RPC debug").Parse(debugText))
+
+// debugService 是渲染调试页面时用到的只读视图
+type debugService struct {
+	Name   string
+	Method map[string]*methodType
+}
+
+type debugHTTP struct {
+	*Server
+}
+
+// ServeHTTP 列出已注册的服务及其方法(参数/返回值类型、调用次数)
+func (server debugHTTP) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var services []debugService
+	server.serviceMap.Range(func(namei, svci interface{}) bool {
+		svc := svci.(*service)
+		services = append(services, debugService{
+			Name:   namei.(string),
+			Method: svc.method,
+		})
+		return true
+	})
+	if err := debug.Execute(w, services); err != nil {
+		_, _ = fmt.Fprintln(w, "rpc: error executing template:", err.Error())
+	}
+}
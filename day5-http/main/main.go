@@ -0,0 +1,49 @@
+package main
+
+import (
+	"cwrpc"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Foo int
+
+type Args struct{ Num1, Num2 int }
+
+func (f Foo) Sum(args Args, reply *int) error {
+	*reply = args.Num1 + args.Num2
+	return nil
+}
+
+// startServer 注册服务并把它暴露在 HTTP 上, rpc 走 CONNECT 隧道, 调试页面直接 GET
+func startServer(addr chan string) {
+	var foo Foo
+	if err := cwrpc.Register(&foo); err != nil {
+		log.Fatal("register error:", err)
+	}
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatal("network error:", err)
+	}
+	cwrpc.HandleHTTP("/_cwrpc_", "/debug/cwrpc")
+	addr <- l.Addr().String()
+	_ = http.Serve(l, nil)
+}
+
+func main() {
+	log.SetFlags(0)
+	addr := make(chan string)
+	go startServer(addr)
+
+	client, _ := cwrpc.DialHTTP("tcp", <-addr)
+	defer func() { _ = client.Close() }()
+
+	time.Sleep(time.Second)
+	var reply int
+	if err := client.Call("Foo.Sum", &Args{Num1: 1, Num2: 2}, &reply); err != nil {
+		log.Fatal("call Foo.Sum error:", err)
+	}
+	log.Println("Foo.Sum:", reply)
+}
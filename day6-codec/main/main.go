@@ -0,0 +1,51 @@
+package main
+
+import (
+	"cwrpc"
+	"cwrpc/codec"
+	"log"
+	"net"
+	"time"
+)
+
+type Foo int
+
+type Args struct{ Num1, Num2 int }
+
+func (f Foo) Sum(args Args, reply *int) error {
+	*reply = args.Num1 + args.Num2
+	return nil
+}
+
+func startServer(addr chan string) {
+	var foo Foo
+	if err := cwrpc.Register(&foo); err != nil {
+		log.Fatal("register error:", err)
+	}
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatal("network error:", err)
+	}
+	addr <- l.Addr().String()
+	cwrpc.Accept(l)
+}
+
+func main() {
+	log.SetFlags(0)
+	addr := make(chan string)
+	go startServer(addr)
+
+	// 走 json codec, 验证跨语言/curl 也能解析出来的长度前缀帧
+	client, _ := cwrpc.Dial("tcp", <-addr, &cwrpc.Option{
+		MagicNumber: cwrpc.MagicNumber,
+		CodecType:   codec.JsonType,
+	})
+	defer func() { _ = client.Close() }()
+
+	time.Sleep(time.Second)
+	var reply int
+	if err := client.Call("Foo.Sum", &Args{Num1: 1, Num2: 2}, &reply); err != nil {
+		log.Fatal("call Foo.Sum error:", err)
+	}
+	log.Println("Foo.Sum:", reply)
+}
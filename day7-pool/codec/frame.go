@@ -0,0 +1,34 @@
+package codec
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// writeFrame 为 payload 加上一个 4 字节大端长度前缀后写入 w
+func writeFrame(w io.Writer, payload []byte) error {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(payload)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame 从 r 中读出一个完整的长度前缀帧, 返回其 payload
+//
+//	这样 ReadHeader/ReadBody 就可以基于同一份已经读完整的字节操作,
+//	不用担心 header 和 body 被拆成两次不相关的网络读取
+func readFrame(r io.Reader) ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(lenBuf[:])
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
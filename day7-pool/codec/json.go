@@ -0,0 +1,72 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+)
+
+// JsonCodec 用 json 编码 header/body, 和 GobCodec 共用同样的
+// 长度前缀组帧方案, 使得跨语言客户端(或者 curl 这类手工调试)成为可能
+type JsonCodec struct {
+	conn io.ReadWriteCloser
+	buf  *bufio.Writer
+	dec  *json.Decoder // 针对当前帧 payload 的解码器, 每次 ReadHeader 都会重建
+}
+
+var _ Codec = (*JsonCodec)(nil)
+
+// NewJsonCodec 创建一个 json 编码的 codec
+func NewJsonCodec(conn io.ReadWriteCloser) Codec {
+	buf := bufio.NewWriter(conn)
+	return &JsonCodec{
+		conn: conn,
+		buf:  buf,
+	}
+}
+
+func (j *JsonCodec) Close() error {
+	return j.conn.Close()
+}
+
+func (j *JsonCodec) ReadHeader(header *Header) error {
+	frame, err := readFrame(j.conn)
+	if err != nil {
+		return err
+	}
+	j.dec = json.NewDecoder(bytes.NewReader(frame))
+	return j.dec.Decode(header)
+}
+
+func (j *JsonCodec) ReadBody(i interface{}) error {
+	// i 为 nil 表示调用方要丢弃这个 body(比如响应已经带了 error), json.Decoder
+	// 不像 gob 那样能安静地接受 nil, 这里手动 no-op 掉保持两个 codec 行为一致
+	if i == nil {
+		return nil
+	}
+	return j.dec.Decode(i)
+}
+
+func (j *JsonCodec) Write(header *Header, i interface{}) (err error) {
+	defer func() {
+		_ = j.buf.Flush()
+		if err != nil {
+			_ = j.Close()
+		}
+	}()
+
+	var payload bytes.Buffer
+	enc := json.NewEncoder(&payload)
+	if err = enc.Encode(header); err != nil {
+		log.Println("rpc: json error encoding header:", err)
+		return
+	}
+	if err = enc.Encode(i); err != nil {
+		log.Println("rpc: json error encoding body:", err)
+		return
+	}
+
+	return writeFrame(j.buf, payload.Bytes())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"cwrpc"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+type Foo int
+
+type Args struct{ Num1, Num2 int }
+
+func (f Foo) Sum(args Args, reply *int) error {
+	*reply = args.Num1 + args.Num2
+	return nil
+}
+
+func startServer(addr chan string) {
+	var foo Foo
+	if err := cwrpc.Register(&foo); err != nil {
+		log.Fatal("register error:", err)
+	}
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatal("network error:", err)
+	}
+	addr <- l.Addr().String()
+	cwrpc.Accept(l)
+}
+
+func main() {
+	log.SetFlags(0)
+	addr := make(chan string)
+	go startServer(addr)
+	serverAddr := <-addr
+	time.Sleep(time.Second)
+
+	// 多个 goroutine 共享同一个连接池, 验证 Get/Put 的复用和并发安全
+	pool := cwrpc.NewClientPool("tcp", serverAddr, cwrpc.DefaultOption, &cwrpc.PoolOption{
+		MaxIdle: 3,
+	})
+	defer func() { _ = pool.Close() }()
+
+	// 预热: 依次建立好 3 条连接, 全部攒够之后再一起放回空闲队列,
+	// 后面的并发调用才能真正复用到互不相同的连接
+	warm := make([]*cwrpc.Client, 0, 3)
+	for i := 0; i < 3; i++ {
+		client, err := pool.Get()
+		if err != nil {
+			log.Fatal("warm up pool error:", err)
+		}
+		warm = append(warm, client)
+	}
+	for _, client := range warm {
+		pool.Put(client)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var reply int
+			if err := pool.Call("Foo.Sum", &Args{Num1: i, Num2: i}, &reply); err != nil {
+				log.Fatal("call Foo.Sum error:", err)
+			}
+			log.Printf("%d + %d = %d\n", i, i, reply)
+		}(i)
+	}
+	wg.Wait()
+}
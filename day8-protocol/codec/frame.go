@@ -0,0 +1,144 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Compression 协商好的帧体压缩方式
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+)
+
+// Checksum 协商好的帧体校验方式
+type Checksum string
+
+const (
+	ChecksumNone  Checksum = "none"
+	ChecksumCRC32 Checksum = "crc32"
+)
+
+// ValidCompression 判断 c 是否是这个 codec 包认识的压缩方式.
+// snappy 被放到下一轮再实现(需要引入额外依赖), 这一版只支持 none/gzip
+func ValidCompression(c Compression) bool {
+	switch c {
+	case "", CompressionNone, CompressionGzip:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidChecksum 判断 c 是否是这个 codec 包认识的校验方式
+func ValidChecksum(c Checksum) bool {
+	switch c {
+	case "", ChecksumNone, ChecksumCRC32:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeFrame 按需 gzip 压缩、按需追加 crc32 校验和, 最后加上 4 字节大端
+// 长度前缀整体写出去, 这样读的一方可以一次性取出完整的一帧
+func writeFrame(w io.Writer, payload []byte, compression Compression, checksum Checksum) error {
+	payload, err := compressPayload(payload, compression)
+	if err != nil {
+		return err
+	}
+	payload = appendChecksum(payload, checksum)
+
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(payload)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(payload)
+	return err
+}
+
+// readFrame 读出一帧, 校验并解压后返回 {header,body} 的原始 payload
+func readFrame(r io.Reader, compression Compression, checksum Checksum) ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(lenBuf[:])
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	payload, err := verifyChecksum(payload, checksum)
+	if err != nil {
+		return nil, err
+	}
+	return decompressPayload(payload, compression)
+}
+
+func compressPayload(payload []byte, compression Compression) ([]byte, error) {
+	switch compression {
+	case "", CompressionNone:
+		return payload, nil
+	case CompressionGzip:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("codec: unsupported compression %q", compression)
+	}
+}
+
+func decompressPayload(payload []byte, compression Compression) ([]byte, error) {
+	switch compression {
+	case "", CompressionNone:
+		return payload, nil
+	case CompressionGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return io.ReadAll(gz)
+	default:
+		return nil, fmt.Errorf("codec: unsupported compression %q", compression)
+	}
+}
+
+func appendChecksum(payload []byte, checksum Checksum) []byte {
+	if checksum != ChecksumCRC32 {
+		return payload
+	}
+	var sumBuf [4]byte
+	binary.BigEndian.PutUint32(sumBuf[:], crc32.ChecksumIEEE(payload))
+	return append(payload, sumBuf[:]...)
+}
+
+func verifyChecksum(payload []byte, checksum Checksum) ([]byte, error) {
+	if checksum != ChecksumCRC32 {
+		return payload, nil
+	}
+	if len(payload) < 4 {
+		return nil, errors.New("codec: frame too short to contain a crc32 checksum")
+	}
+	body, sumBytes := payload[:len(payload)-4], payload[len(payload)-4:]
+	want := binary.BigEndian.Uint32(sumBytes)
+	if got := crc32.ChecksumIEEE(body); got != want {
+		return nil, fmt.Errorf("codec: crc32 checksum mismatch: got %x want %x", got, want)
+	}
+	return body, nil
+}
@@ -0,0 +1,84 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"io"
+	"log"
+)
+
+// 定义Gob解码器
+
+type GobCodec struct {
+	conn        io.ReadWriteCloser // 网络conn
+	buf         *bufio.Writer      // buf 缓冲
+	dec         *gob.Decoder       // 针对当前帧 payload 的解码器, 每次 ReadHeader 都会重建
+	compression Compression
+	checksum    Checksum
+}
+
+// 这行代码是 Go 语言中的一种类型断言的写法，用于检查 `*GobCodec` 类型是否实现了 `Codec` 接口。
+// 在这行代码中，`var _ Codec` 定义了一个匿名变量，类型为 `Codec` 接口。然后，`(*GobCodec)(nil)` 是一个类型为 `*GobCodec` 的空指针。
+// 通过将空指针赋值给匿名变量，编译器会在编译时检查 `*GobCodec` 类型是否实现了 `Codec` 接口。如果 `*GobCodec` 类型没有实现 `Codec` 接口，编译器会在编译时报错。
+// 这种写法通常用于确保某个类型实现了特定的接口，以避免在运行时出现错误。如果编译通过，说明 `*GobCodec` 类型确实实现了 `Codec` 接口。
+var _ Codec = (*GobCodec)(nil)
+
+// NewGobCodec 创建一个 gob 编码的 codec, compression/checksum 是握手协商好的选项
+func NewGobCodec(conn io.ReadWriteCloser, compression Compression, checksum Checksum) Codec {
+	buf := bufio.NewWriter(conn)
+	return &GobCodec{
+		conn:        conn,
+		buf:         buf,
+		compression: compression,
+		checksum:    checksum,
+	}
+}
+
+// 实现codec接口
+func (g *GobCodec) Close() error {
+	return g.conn.Close()
+}
+
+// ReadHeader 把一整帧读进内存(校验/解压后)再解码 header, 剩下的 body 字节
+// 留给后面的 ReadBody 使用同一个 decoder 继续解
+func (g *GobCodec) ReadHeader(header *Header) error {
+	frame, err := readFrame(g.conn, g.compression, g.checksum)
+	if err != nil {
+		return err
+	}
+	g.dec = gob.NewDecoder(bytes.NewReader(frame))
+	return g.dec.Decode(header)
+}
+
+func (g *GobCodec) ReadBody(i interface{}) error {
+	// 将数据从conn读取出来,解码后数据写到i中
+	return g.dec.Decode(i)
+}
+
+// 将header和body数据写入到 conn中, 数据线写入一个buf缓冲中, 然后在写入 conn中的
+func (g *GobCodec) Write(header *Header, i interface{}) (err error) {
+	// 压入栈中
+	defer func() {
+		// 将数据刷写到 conn中
+		_ = g.buf.Flush()
+		if err != nil {
+			_ = g.Close()
+		}
+	}()
+
+	// header和body先编码到一个缓冲区, 再按协商好的压缩/校验方式整体组帧写出去
+	var payload bytes.Buffer
+	enc := gob.NewEncoder(&payload)
+	if err = enc.Encode(header); err != nil {
+		log.Println("rpc: gob error encoding header:", err)
+		return
+	}
+
+	if err = enc.Encode(i); err != nil {
+		log.Println("rpc: gob error encoding body:", err)
+		return
+	}
+
+	return writeFrame(g.buf, payload.Bytes(), g.compression, g.checksum)
+}
@@ -0,0 +1,41 @@
+package cwrpc
+
+import (
+	"io"
+	"log"
+	"net/http"
+)
+
+const (
+	defaultRPCPath   = "/_cwrpc_"
+	defaultDebugPath = "/debug/cwrpc"
+	connected        = "200 Connected to CodeWave RPC"
+)
+
+// ServeHTTP 实现了一个 http.Handler, 通过 HTTP CONNECT 请求响应 RPC 请求
+func (server *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "CONNECT" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = io.WriteString(w, "405 must CONNECT\n")
+		return
+	}
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		log.Print("rpc hijacking ", req.RemoteAddr, ": ", err.Error())
+		return
+	}
+	_, _ = io.WriteString(conn, "HTTP/1.0 "+connected+"\n\n")
+	server.ServeConn(conn)
+}
+
+// HandleHTTP 在 rpcPath 上注册处理 RPC 消息的 handler, 在 debugPath 上注册调试页面
+func (server *Server) HandleHTTP(rpcPath, debugPath string) {
+	http.Handle(rpcPath, server)
+	http.Handle(debugPath, debugHTTP{server})
+}
+
+// HandleHTTP 将 DefaultServer 注册到 rpcPath/debugPath 上
+func HandleHTTP(rpcPath, debugPath string) {
+	DefaultServer.HandleHTTP(rpcPath, debugPath)
+}
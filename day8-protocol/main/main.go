@@ -0,0 +1,70 @@
+package main
+
+import (
+	"cwrpc"
+	"cwrpc/codec"
+	"log"
+	"net"
+	"time"
+)
+
+type Foo int
+
+type Args struct{ Num1, Num2 int }
+
+func (f Foo) Sum(args Args, reply *int) error {
+	*reply = args.Num1 + args.Num2
+	return nil
+}
+
+func startServer(addr chan string) {
+	var foo Foo
+	if err := cwrpc.Register(&foo); err != nil {
+		log.Fatal("register error:", err)
+	}
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatal("network error:", err)
+	}
+	addr <- l.Addr().String()
+	cwrpc.Accept(l)
+}
+
+func main() {
+	log.SetFlags(0)
+	addr := make(chan string)
+	go startServer(addr)
+	serverAddr := <-addr
+	time.Sleep(time.Second)
+
+	// 握手阶段协商 gzip 压缩 + crc32 校验, ServeConn 在进入 serveCodec 之前就会校验这两个字段
+	opt := &cwrpc.Option{
+		MagicNumber:    cwrpc.MagicNumber,
+		CodecType:      codec.GobType,
+		ConnectTimeout: 10 * time.Second,
+		Compression:    codec.CompressionGzip,
+		Checksum:       codec.ChecksumCRC32,
+	}
+	client, err := cwrpc.Dial("tcp", serverAddr, opt)
+	if err != nil {
+		log.Fatal("dial error:", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	var reply int
+	if err := client.Call("Foo.Sum", &Args{Num1: 1, Num2: 2}, &reply); err != nil {
+		log.Fatal("call Foo.Sum error:", err)
+	}
+	log.Printf("1 + 2 = %d\n", reply)
+
+	// 本地请求一个这个 codec 包不认识的压缩方式, Dial 应该直接失败并返回 ErrProtocolMismatch
+	_, err = cwrpc.Dial("tcp", serverAddr, &cwrpc.Option{
+		MagicNumber: cwrpc.MagicNumber,
+		CodecType:   codec.GobType,
+		Compression: "lz4",
+	})
+	if err != cwrpc.ErrProtocolMismatch {
+		log.Fatalf("expected ErrProtocolMismatch, got %v", err)
+	}
+	log.Println("rejected unsupported compression as expected:", err)
+}
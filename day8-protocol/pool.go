@@ -0,0 +1,172 @@
+package cwrpc
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// PoolOption 控制 ClientPool 的行为
+type PoolOption struct {
+	MaxIdle     int           // 最大空闲连接数
+	MaxActive   int           // 最大活跃(已创建且未关闭)连接数, 0 表示不限制
+	IdleTimeout time.Duration // 空闲连接超过这个时间会被回收, 0 表示不超时
+}
+
+// DefaultPoolOption 默认的连接池参数
+var DefaultPoolOption = &PoolOption{
+	MaxIdle:   5,
+	MaxActive: 0,
+}
+
+// ErrPoolExhausted 连接池已经达到 MaxActive 上限
+var ErrPoolExhausted = errors.New("rpc client pool: connection pool exhausted")
+
+type idleClient struct {
+	client *Client
+	idleAt time.Time
+}
+
+// ClientPool 维护一组指向同一个 (network, address, *Option) 的 *Client,
+// 按需通过 Dial 重连, 并用 Client.IsAvailable 剔除已经失效的连接
+type ClientPool struct {
+	network string
+	address string
+	opt     *Option
+	poolOpt *PoolOption
+
+	mu     sync.Mutex
+	idle   []idleClient
+	active int
+}
+
+// NewClientPool 创建一个指向 network/address 的连接池
+func NewClientPool(network, address string, opt *Option, poolOpt *PoolOption) *ClientPool {
+	if poolOpt == nil {
+		poolOpt = DefaultPoolOption
+	}
+	return &ClientPool{
+		network: network,
+		address: address,
+		opt:     opt,
+		poolOpt: poolOpt,
+	}
+}
+
+// dialOption 返回一份 p.opt 的拷贝, 调用方必须持有 p.mu.
+// Dial 最终会走到 parseOptions, 后者会就地改写传入的 *Option, 如果多个
+// Get() 都传同一个 *Option 指针就会在它的字段上产生数据竞争
+func (p *ClientPool) dialOption() *Option {
+	if p.opt == nil {
+		return nil
+	}
+	opt := *p.opt
+	return &opt
+}
+
+// Get 取出一个可用的 *Client, 优先复用空闲连接, 否则在 MaxActive 允许范围内新建
+func (p *ClientPool) Get() (*Client, error) {
+	p.mu.Lock()
+	for len(p.idle) > 0 {
+		ic := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if p.poolOpt.IdleTimeout > 0 && time.Since(ic.idleAt) > p.poolOpt.IdleTimeout {
+			p.active--
+			_ = ic.client.Close()
+			continue
+		}
+		if !ic.client.IsAvailable() {
+			p.active--
+			continue
+		}
+		p.mu.Unlock()
+		return ic.client, nil
+	}
+	if p.poolOpt.MaxActive > 0 && p.active >= p.poolOpt.MaxActive {
+		p.mu.Unlock()
+		return nil, ErrPoolExhausted
+	}
+	p.active++
+	// parseOptions 会就地改写传入的 *Option, 这里拷贝一份出来传给 Dial,
+	// 避免并发的 Get() 都指向 p.opt 同一个实例而相互踩踏
+	opt := p.dialOption()
+	p.mu.Unlock()
+
+	client, err := Dial(p.network, p.address, opt)
+	if err != nil {
+		p.mu.Lock()
+		p.active--
+		p.mu.Unlock()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Put 把 client 放回空闲队列; 已经失效或者空闲队列已满时直接关闭丢弃
+func (p *ClientPool) Put(client *Client) {
+	if client == nil {
+		return
+	}
+	p.mu.Lock()
+	if !client.IsAvailable() || len(p.idle) >= p.poolOpt.MaxIdle {
+		p.active--
+		p.mu.Unlock()
+		_ = client.Close()
+		return
+	}
+	p.idle = append(p.idle, idleClient{client: client, idleAt: time.Now()})
+	p.mu.Unlock()
+}
+
+// Call 从池中取出一个 client 完成同步调用, 调用结束后自动放回池中
+func (p *ClientPool) Call(serviceMethod string, args, reply interface{}) error {
+	client, err := p.Get()
+	if err != nil {
+		return err
+	}
+	defer p.Put(client)
+	return client.Call(serviceMethod, args, reply)
+}
+
+// Go 从池中取出一个 client 发起异步调用, 调用完成后自动放回池中再通知 done
+func (p *ClientPool) Go(serviceMethod string, args, reply interface{}, done chan *Call) *Call {
+	client, err := p.Get()
+	if err != nil {
+		if done == nil {
+			done = make(chan *Call, 1)
+		}
+		call := &Call{ServiceMethod: serviceMethod, Args: args, Reply: reply, Error: err, Done: done}
+		call.done()
+		return call
+	}
+	if done == nil {
+		done = make(chan *Call, 10)
+	}
+	// call 是返回给调用方的对象, Done 是调用方的 done; relay 只给下面这个转发
+	// goroutine 自己用, 避免调用方和转发 goroutine 两边都在读同一个 channel
+	call := &Call{ServiceMethod: serviceMethod, Args: args, Reply: reply, Done: done}
+	relay := make(chan *Call, 1)
+	client.Go(serviceMethod, args, reply, relay)
+	go func() {
+		finished := <-relay
+		p.Put(client)
+		call.Seq = finished.Seq
+		call.Error = finished.Error
+		call.done()
+	}()
+	return call
+}
+
+// Close 关闭池中所有空闲连接
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var err error
+	for _, ic := range p.idle {
+		if cerr := ic.client.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	p.idle = nil
+	return err
+}
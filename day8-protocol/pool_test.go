@@ -0,0 +1,59 @@
+package cwrpc
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type PoolFoo int
+
+// PoolArgs 必须导出, 否则 service.registerMethods 会把 Sum 方法跳过
+type PoolArgs struct{ A, B int }
+
+func (PoolFoo) Sum(args PoolArgs, reply *int) error {
+	*reply = args.A + args.B
+	return nil
+}
+
+// TestClientPoolGoDoesNotLeakUnderConcurrency 并发调用 pool.Go 并只通过调用方自己的
+// done channel 等待结果(和 net/rpc 的惯用法一样), 验证每个 client 用完之后真的被
+// p.Put 放回了池子: 如果转发 goroutine 和调用方都在读同一个 relay channel, 会有一半
+// 左右的调用"赢得"这场竞争, 导致 p.Put 永远不会执行, MaxActive 很快就被占满
+func TestClientPoolGoDoesNotLeakUnderConcurrency(t *testing.T) {
+	var foo PoolFoo
+	server := NewServer()
+	if err := server.Register(&foo); err != nil {
+		t.Fatalf("register error: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+	go server.Accept(l)
+
+	pool := NewClientPool("tcp", l.Addr().String(), DefaultOption, &PoolOption{MaxActive: 2})
+	defer func() { _ = pool.Close() }()
+
+	const rounds = 20
+	var wg sync.WaitGroup
+	for i := 0; i < rounds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			done := make(chan *Call, 1)
+			call := pool.Go("PoolFoo.Sum", PoolArgs{A: i, B: i}, new(int), done)
+			select {
+			case finished := <-call.Done:
+				if finished.Error != nil {
+					t.Errorf("call %d failed: %v", i, finished.Error)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("call %d: timed out waiting on call.Done, client was never returned to the pool", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
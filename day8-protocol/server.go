@@ -0,0 +1,289 @@
+package cwrpc
+
+import (
+	"bufio"
+	"cwrpc/codec"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 定义一个魔数
+const MagicNumber = 0x3bef5c
+
+// option
+type Option struct {
+	MagicNumber    int
+	CodecType      codec.Type
+	ConnectTimeout time.Duration // 0 表示不限制
+	HandleTimeout  time.Duration
+	Compression    codec.Compression // 帧体压缩方式, 空值等价于 codec.CompressionNone
+	Checksum       codec.Checksum    // 帧体校验方式, 空值等价于 codec.ChecksumNone
+}
+
+// 默认编码格式
+var DefaultOption = &Option{
+	MagicNumber:    MagicNumber,
+	CodecType:      codec.GobType,
+	ConnectTimeout: 10 * time.Second,
+	Compression:    codec.CompressionNone,
+	Checksum:       codec.ChecksumNone,
+}
+
+type Server struct {
+	// 服务名 -> *service
+	serviceMap sync.Map
+}
+
+func NewServer() *Server {
+	return &Server{}
+}
+
+var DefaultServer = NewServer()
+
+// Register 将 rcvr 的所有符合条件的方法发布为服务
+func (server *Server) Register(rcvr interface{}) error {
+	s := newService(rcvr)
+	if _, dup := server.serviceMap.LoadOrStore(s.name, s); dup {
+		return errors.New("rpc: service already defined: " + s.name)
+	}
+	return nil
+}
+
+// RegisterName 和 Register 类似, 但是用传入的 name 作为服务名
+func (server *Server) RegisterName(name string, rcvr interface{}) error {
+	s := newService(rcvr)
+	s.name = name
+	if _, dup := server.serviceMap.LoadOrStore(s.name, s); dup {
+		return errors.New("rpc: service already defined: " + s.name)
+	}
+	return nil
+}
+
+// Register 注册到 DefaultServer 上
+func Register(rcvr interface{}) error { return DefaultServer.Register(rcvr) }
+
+// RegisterName 注册到 DefaultServer 上
+func RegisterName(name string, rcvr interface{}) error { return DefaultServer.RegisterName(name, rcvr) }
+
+// 根据 "Service.Method" 找到对应的 *service 和 *methodType
+func (server *Server) findService(serviceMethod string) (svc *service, mtype *methodType, err error) {
+	dot := strings.LastIndex(serviceMethod, ".")
+	if dot < 0 {
+		err = errors.New("rpc server: service/method request ill-formed: " + serviceMethod)
+		return
+	}
+	serviceName, methodName := serviceMethod[:dot], serviceMethod[dot+1:]
+	svci, ok := server.serviceMap.Load(serviceName)
+	if !ok {
+		err = errors.New("rpc server: can't find service " + serviceName)
+		return
+	}
+	svc = svci.(*service)
+	mtype = svc.method[methodName]
+	if mtype == nil {
+		err = errors.New("rpc server: can't find method " + methodName)
+	}
+	return
+}
+
+func (server *Server) ServeConn(conn io.ReadWriteCloser) {
+	defer func() { _ = conn.Close() }()
+	var opt Option
+	// 先从 conn 中解码出客户端发来的 Option
+	dec := json.NewDecoder(conn)
+	if err := dec.Decode(&opt); err != nil {
+		log.Println("rpc server: options error: ", err)
+		return
+	}
+	//  简单验证
+	if opt.MagicNumber != MagicNumber {
+		log.Printf("rpc server: invalid magic number %x", opt.MagicNumber)
+		return
+	}
+
+	f := codec.NewCodecFuncMap[opt.CodecType]
+	if f == nil {
+		log.Printf("rpc server: invalid codec type %s", opt.CodecType)
+		return
+	}
+	// 在进入 serveCodec 之前就把不认识的 compression/checksum 拒绝掉
+	if !codec.ValidCompression(opt.Compression) {
+		log.Printf("rpc server: invalid compression %s", opt.Compression)
+		return
+	}
+	if !codec.ValidChecksum(opt.Checksum) {
+		log.Printf("rpc server: invalid checksum %s", opt.Checksum)
+		return
+	}
+
+	// dec 可能已经把 Option 后面的帧读进了自己的缓冲, 也可能还没读到 Encode 追加的换行;
+	// 把缓冲接回 conn 前面并跳过这个换行, codec 才能从帧的开头读起
+	br := bufio.NewReader(io.MultiReader(dec.Buffered(), conn))
+	if b, err := br.Peek(1); err == nil && b[0] == '\n' {
+		_, _ = br.Discard(1)
+	}
+	rwc := struct {
+		io.Reader
+		io.Writer
+		io.Closer
+	}{br, conn, conn}
+	server.serveCodec(f(rwc, opt.Compression, opt.Checksum), &opt)
+}
+
+var invalidRequest = struct{}{}
+
+// 相当于启动一个服务
+//
+//	网络连接包装到 codec中了
+func (server *Server) serveCodec(cc codec.Codec, opt *Option) {
+	sending := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+	for {
+		// req是包含 header和argv参数
+		req, err := server.readRequest(cc)
+		if err != nil {
+			if req == nil {
+				break // it's not possible to recover, so close the connection
+			}
+			// 将 error放到 header中
+			req.h.Error = err.Error()
+			server.sendResponse(cc, req.h, invalidRequest, sending)
+			continue
+		}
+		wg.Add(1)
+		go server.handleRequest(cc, req, sending, wg, opt.HandleTimeout)
+	}
+	wg.Wait()
+	_ = cc.Close()
+}
+
+type request struct {
+	h            *codec.Header
+	argv, replyv reflect.Value
+	mtype        *methodType
+	svc          *service
+}
+
+func (s *Server) readRequestHeader(cc codec.Codec) (*codec.Header, error) {
+	var h codec.Header
+	// 从conn中读出 header
+	if err := cc.ReadHeader(&h); err != nil {
+		// 如果结束或异常, 就 返回
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Println("rpc server: read header error:", err)
+		}
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (s *Server) readRequest(cc codec.Codec) (*request, error) {
+	//  读取出请求头
+	h, err := s.readRequestHeader(cc)
+	if err != nil {
+		return nil, err
+	}
+	// 构造 request
+	req := &request{h: h}
+	// 根据 header中的 ServiceMethod 找到对应的 service 和 method
+	req.svc, req.mtype, err = s.findService(h.ServiceMethod)
+	if err != nil {
+		return req, err
+	}
+	// 根据 method 构造出 argv 和 replyv
+	req.argv = req.mtype.newArgv()
+	req.replyv = req.mtype.newReplyv()
+
+	// 读取出 body写到 argv中, ReadBody需要一个指针
+	argvi := req.argv.Interface()
+	if req.argv.Type().Kind() != reflect.Ptr {
+		argvi = req.argv.Addr().Interface()
+	}
+	if err = cc.ReadBody(argvi); err != nil {
+		log.Println("rpc server: read argv err:", err)
+		return req, err
+	}
+	return req, nil
+}
+
+// 服务端返回响应
+func (server *Server) sendResponse(cc codec.Codec, h *codec.Header, body interface{}, sending *sync.Mutex) {
+	sending.Lock()
+	defer sending.Unlock()
+	// 将header和 body写到conn中
+	if err := cc.Write(h, body); err != nil {
+		log.Println("rpc server: write response error:", err)
+	}
+}
+
+// handleRequest 真正调用注册的方法, 并在 timeout 内没有完成时提前返回超时错误
+//
+//	once 保证 timeout 分支和正常完成分支里只有先到的那个会调用 sendResponse,
+//	慢的那个结果直接丢弃; 两边各自用 req.h 的一份拷贝, 不共享可变的 Header,
+//	这样就算晚到的一侧还在写 Error 字段, 也不会和先发出去的那次响应产生数据竞争
+func (server *Server) handleRequest(cc codec.Codec, req *request, sending *sync.Mutex, wg *sync.WaitGroup, timeout time.Duration) {
+	defer wg.Done()
+	// 带 1 个缓冲, 这样 timeout 分支抢先返回之后, goroutine 自己还能写完 called/sent 退出, 不会永久阻塞
+	called := make(chan struct{}, 1)
+	sent := make(chan struct{}, 1)
+	var once sync.Once
+	send := func(h *codec.Header, body interface{}) {
+		once.Do(func() {
+			server.sendResponse(cc, h, body, sending)
+		})
+	}
+	go func() {
+		err := req.svc.call(req.mtype, req.argv, req.replyv)
+		called <- struct{}{}
+		h := *req.h
+		if err != nil {
+			h.Error = err.Error()
+			send(&h, invalidRequest)
+			sent <- struct{}{}
+			return
+		}
+		send(&h, req.replyv.Interface())
+		sent <- struct{}{}
+	}()
+
+	if timeout == 0 {
+		<-called
+		<-sent
+		return
+	}
+
+	select {
+	case <-time.After(timeout):
+		h := *req.h
+		h.Error = fmt.Sprintf("rpc server: request handle timeout: expect within %s", timeout)
+		send(&h, invalidRequest)
+	case <-called:
+		<-sent
+	}
+}
+
+// 启动一个服务
+func (server *Server) Accept(lis net.Listener) {
+	for {
+		// 接收,建立一个conn
+		conn, err := lis.Accept()
+		if err != nil {
+			log.Println("rpc server: accept error:", err)
+			return
+		}
+		// 处理一个conn
+		go server.ServeConn(conn)
+	}
+}
+
+// Accept accepts connections on the listener and serves requests
+// for each incoming connection.
+func Accept(lis net.Listener) { DefaultServer.Accept(lis) }
@@ -0,0 +1,77 @@
+package cwrpc
+
+import (
+	"cwrpc/codec"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+type SlowFoo struct{}
+
+// Wait 比测试里设置的 HandleTimeout 慢, 用来触发 handleRequest 的超时分支
+func (SlowFoo) Wait(arg int, reply *int) error {
+	time.Sleep(150 * time.Millisecond)
+	*reply = arg
+	return nil
+}
+
+// TestHandleRequestTimeoutSingleResponse 绕开 Client, 直接对着 net.Pipe 的一端
+// 发请求, 验证 handler 比 HandleTimeout 慢时, 连接上只会出现一帧响应(超时错误),
+// 迟到的正常结果不会被第二次写到线上
+func TestHandleRequestTimeoutSingleResponse(t *testing.T) {
+	server := NewServer()
+	if err := server.Register(&SlowFoo{}); err != nil {
+		t.Fatalf("register error: %v", err)
+	}
+
+	clientConn, serverConn := net.Pipe()
+	defer func() { _ = clientConn.Close() }()
+
+	opt := Option{
+		MagicNumber:   MagicNumber,
+		CodecType:     codec.GobType,
+		HandleTimeout: 20 * time.Millisecond,
+	}
+
+	served := make(chan struct{})
+	go func() {
+		server.ServeConn(serverConn)
+		close(served)
+	}()
+
+	if err := json.NewEncoder(clientConn).Encode(&opt); err != nil {
+		t.Fatalf("send option error: %v", err)
+	}
+
+	cc := codec.NewCodecFuncMap[codec.GobType](clientConn, codec.CompressionNone, codec.ChecksumNone)
+	if err := cc.Write(&codec.Header{ServiceMethod: "SlowFoo.Wait", Seq: 1}, 41); err != nil {
+		t.Fatalf("write request error: %v", err)
+	}
+
+	var h codec.Header
+	if err := cc.ReadHeader(&h); err != nil {
+		t.Fatalf("read header error: %v", err)
+	}
+	if h.Error == "" {
+		t.Fatalf("expected the first and only response to carry the timeout error, got none")
+	}
+	var reply int
+	_ = cc.ReadBody(&reply)
+
+	// 给迟到的 handler 留足时间跑完, 确认它不会再往同一个连接上补发第二帧
+	readDone := make(chan error, 1)
+	go func() {
+		var second codec.Header
+		readDone <- cc.ReadHeader(&second)
+	}()
+	select {
+	case err := <-readDone:
+		t.Fatalf("unexpected second response frame on the wire (err=%v)", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	_ = clientConn.Close()
+	<-served
+}